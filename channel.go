@@ -0,0 +1,217 @@
+// Copyright © 2021 Felix Schütz
+// Licensed under the MIT license. See the LICENSE file for details.
+
+package simgo
+
+// Channel lets processes exchange typed values in simulated time.
+//
+// An unbuffered channel (capacity 0) rendezvous a Send and a Receive at the
+// same simulated instant. A buffered channel queues up to capacity values,
+// letting Send return without a waiting receiver. Waiters on both sides are
+// served in FIFO order, so simulations stay deterministic.
+//
+// Use NewChannel to create a Channel:
+//
+//	ch := simgo.NewChannel[int](sim, 0)
+//	sim.Process(func(proc simgo.Process) {
+//	    ch.Send(proc, 42)
+//	})
+//	sim.Process(func(proc simgo.Process) {
+//	    v := ch.Receive(proc)
+//	    fmt.Println(v)
+//	})
+type Channel[T any] struct {
+	sim *Simulation
+
+	buffer   []T
+	capacity int
+
+	// senders and receivers are the FIFO queues of parties currently
+	// blocked on Send / Receive, each paired with the event that completes
+	// their side of the handoff.
+	senders   []chanSender[T]
+	receivers []chanReceiver[T]
+
+	closed bool
+}
+
+type chanSender[T any] struct {
+	value T
+	ev    *Event
+}
+
+type chanReceiver[T any] struct {
+	ev     *Event
+	result *chanResult[T]
+}
+
+// chanResult carries the value (and closed flag) delivered to a Receive back
+// to the caller once its event is processed.
+type chanResult[T any] struct {
+	value  T
+	closed bool
+}
+
+// NewChannel creates a Channel with the given buffer capacity. A capacity of
+// 0 creates an unbuffered, rendezvous-only channel.
+func NewChannel[T any](sim *Simulation, capacity int) *Channel[T] {
+	return &Channel[T]{
+		sim:      sim,
+		capacity: capacity,
+	}
+}
+
+// Send blocks the calling process until v has been delivered to a receiver
+// or, for a buffered channel with room, until it has been queued.
+//
+// Sending on a closed channel aborts the event Send waits on, which (per
+// Process.Wait) aborts and kills the calling process with no way to recover
+// - the same failure mode a send on a closed channel has in plain Go. Check
+// Closed first if the caller needs to handle a closed channel gracefully
+// instead.
+func (ch *Channel[T]) Send(proc Process, v T) {
+	proc.Wait(ch.SendEvent(v))
+}
+
+// Receive blocks the calling process until a value is available and returns
+// it. If the channel is closed before a value becomes available, Receive
+// returns the zero value of T.
+func (ch *Channel[T]) Receive(proc Process) T {
+	v, _ := ch.ReceiveEvent().(*channelReceiveEvent[T]).wait(proc)
+	return v
+}
+
+// SendEvent returns an Awaitable that is processed once v has been handed to
+// a waiting receiver or queued in the buffer, or aborted immediately if the
+// channel is already closed - see Send for what that means for a process
+// that Waits on it. It composes with Timeout and other events via AnyOf /
+// AllOf.
+func (ch *Channel[T]) SendEvent(v T) Awaitable {
+	ev := ch.sim.Event()
+
+	if ch.closed {
+		// sending on a closed channel is a programmer error in every
+		// channel implementation this is modelled on; abort instead of
+		// panicking so it plays nicely with Wait.
+		ev.Abort()
+		return ev
+	}
+
+	if len(ch.receivers) > 0 {
+		receiver := ch.receivers[0]
+		ch.receivers = ch.receivers[1:]
+		receiver.result.value = v
+		receiver.ev.Trigger()
+		ev.Trigger()
+
+		return ev
+	}
+
+	if len(ch.buffer) < ch.capacity {
+		ch.buffer = append(ch.buffer, v)
+		ev.Trigger()
+
+		return ev
+	}
+
+	ch.senders = append(ch.senders, chanSender[T]{value: v, ev: ev})
+	ch.sim.traceScheduled(ev, "channel send")
+
+	return ev
+}
+
+// channelReceiveEvent adapts Receive's (value, closed) result onto the plain
+// Awaitable returned by ReceiveEvent, so Wait can be used for either.
+type channelReceiveEvent[T any] struct {
+	*Event
+	result *chanResult[T]
+}
+
+func (e *channelReceiveEvent[T]) wait(proc Process) (T, bool) {
+	proc.Wait(e.Event)
+	return e.result.value, e.result.closed
+}
+
+// ReceiveEvent returns an Awaitable that is processed once a value is
+// available, buffered or from a waiting sender. It composes with Timeout
+// and other events via AnyOf / AllOf. The delivered value and closed flag
+// are held by the returned event, which has no exported accessor for them;
+// calling Receive afterwards starts a new, separate receive.
+func (ch *Channel[T]) ReceiveEvent() Awaitable {
+	result := &chanResult[T]{}
+
+	if len(ch.buffer) > 0 {
+		result.value = ch.buffer[0]
+		ch.buffer = ch.buffer[1:]
+
+		if len(ch.senders) > 0 {
+			sender := ch.senders[0]
+			ch.senders = ch.senders[1:]
+			ch.buffer = append(ch.buffer, sender.value)
+			sender.ev.Trigger()
+		}
+
+		ev := ch.sim.Event()
+		ev.Trigger()
+
+		return &channelReceiveEvent[T]{Event: ev, result: result}
+	}
+
+	if len(ch.senders) > 0 {
+		sender := ch.senders[0]
+		ch.senders = ch.senders[1:]
+		result.value = sender.value
+		sender.ev.Trigger()
+
+		ev := ch.sim.Event()
+		ev.Trigger()
+
+		return &channelReceiveEvent[T]{Event: ev, result: result}
+	}
+
+	if ch.closed {
+		result.closed = true
+
+		ev := ch.sim.Event()
+		ev.Trigger()
+
+		return &channelReceiveEvent[T]{Event: ev, result: result}
+	}
+
+	ev := ch.sim.Event()
+	ch.receivers = append(ch.receivers, chanReceiver[T]{ev: ev, result: result})
+	ch.sim.traceScheduled(ev, "channel receive")
+
+	return &channelReceiveEvent[T]{Event: ev, result: result}
+}
+
+// Close closes the channel, waking every blocked receiver with the zero
+// value of T and a closed flag, and aborting every blocked sender since
+// there is no longer any prospect of a receiver claiming its value. Sends
+// already queued in the buffer are still delivered; further sends abort
+// instead of blocking.
+func (ch *Channel[T]) Close() {
+	ch.closed = true
+
+	receivers := ch.receivers
+	ch.receivers = nil
+
+	for _, receiver := range receivers {
+		receiver.result.closed = true
+		receiver.ev.Trigger()
+	}
+
+	senders := ch.senders
+	ch.senders = nil
+
+	for _, sender := range senders {
+		sender.ev.Abort()
+	}
+}
+
+// Closed reports whether the channel has been closed. A sender that wants to
+// handle a closed channel gracefully, instead of having Send/SendEvent abort
+// and kill the process, should check Closed first.
+func (ch *Channel[T]) Closed() bool {
+	return ch.closed
+}
@@ -0,0 +1,136 @@
+// Copyright © 2021 Felix Schütz
+// Licensed under the MIT license. See the LICENSE file for details.
+
+package simgo
+
+import "testing"
+
+// TestChannelRendezvous is a regression test for an unbuffered Channel:
+// Send must block until a Receive is ready, and both sides complete at the
+// same simulated time.
+func TestChannelRendezvous(t *testing.T) {
+	sim := NewSimulation()
+	ch := NewChannel[int](sim, 0)
+
+	var sentAt, receivedAt float64
+	var got int
+
+	sim.Process(func(proc Process) {
+		proc.Wait(proc.Timeout(5))
+		ch.Send(proc, 42)
+		sentAt = proc.Now()
+	})
+
+	sim.Process(func(proc Process) {
+		got = ch.Receive(proc)
+		receivedAt = proc.Now()
+	})
+
+	sim.Run()
+
+	if got != 42 {
+		t.Fatalf("Receive() = %v, want 42", got)
+	}
+
+	if sentAt != 5 || receivedAt != 5 {
+		t.Fatalf("sentAt = %v, receivedAt = %v, want both 5", sentAt, receivedAt)
+	}
+}
+
+// TestChannelBuffering is a regression test for a buffered Channel: Send
+// must return without waiting for a receiver as long as there is room in the
+// buffer, and queued values must be delivered in FIFO order.
+func TestChannelBuffering(t *testing.T) {
+	sim := NewSimulation()
+	ch := NewChannel[int](sim, 2)
+
+	var sendReturned bool
+
+	sim.Process(func(proc Process) {
+		ch.Send(proc, 1)
+		ch.Send(proc, 2)
+		sendReturned = true
+	})
+
+	var got []int
+
+	sim.Process(func(proc Process) {
+		proc.Wait(proc.Timeout(1))
+		got = append(got, ch.Receive(proc))
+		got = append(got, ch.Receive(proc))
+	})
+
+	sim.Run()
+
+	if !sendReturned {
+		t.Fatal("both buffered sends should have returned without a waiting receiver")
+	}
+
+	if len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("got = %v, want [1 2] (FIFO order)", got)
+	}
+}
+
+// TestChannelCloseWakesReceivers is a regression test for Close: a receiver
+// already queued when the channel closes must wake up with the zero value
+// and a closed flag instead of blocking forever.
+func TestChannelCloseWakesReceivers(t *testing.T) {
+	sim := NewSimulation()
+	ch := NewChannel[string](sim, 0)
+
+	var got string
+	var closed bool
+	var woke bool
+
+	sim.Process(func(proc Process) {
+		got, closed = ch.ReceiveEvent().(*channelReceiveEvent[string]).wait(proc)
+		woke = true
+	})
+
+	sim.Process(func(proc Process) {
+		proc.Wait(proc.Timeout(1))
+		ch.Close()
+	})
+
+	sim.Run()
+
+	if !woke {
+		t.Fatal("queued receiver was never woken by Close")
+	}
+
+	if !closed || got != "" {
+		t.Fatalf("Receive result = (%q, %v), want (\"\", true)", got, closed)
+	}
+}
+
+// TestChannelSendOnClosedAbortsProcess is a regression test for the
+// documented failure mode of Send/SendEvent: sending on an already-closed
+// channel aborts the event, which aborts and kills the sending process
+// (Process.Wait's abort path calls runtime.Goexit, so the rest of the
+// process body never runs).
+func TestChannelSendOnClosedAbortsProcess(t *testing.T) {
+	sim := NewSimulation()
+	ch := NewChannel[int](sim, 0)
+	ch.Close()
+
+	var ranAfterSend bool
+
+	sender := sim.Process(func(proc Process) {
+		ch.Send(proc, 1)
+		ranAfterSend = true
+	})
+
+	sim.Run()
+
+	if ranAfterSend {
+		t.Fatal("process code after Send on a closed channel ran, want it killed instead")
+	}
+
+	if !sender.Aborted() {
+		t.Fatal("sender process was not aborted after sending on a closed channel")
+	}
+
+	if !ch.Closed() {
+		t.Fatal("Closed() = false after Close, want true")
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright © 2021 Felix Schütz
+// Licensed under the MIT license. See the LICENSE file for details.
+
+package simgo
+
+// CombinedResult reports, for a combinator such as AnyOf or AllOf, which of
+// the constituent Awaitables were processed and which were aborted by the
+// time the combined event triggered.
+type CombinedResult struct {
+	Processed []Awaitable
+	Aborted   []Awaitable
+}
+
+// AnyOf returns an Awaitable that is processed as soon as the first of evs
+// is processed, carrying a CombinedResult reachable via sim.Result once
+// triggered. An aborted branch is ignored unless every branch aborts, in
+// which case the combined event itself is aborted.
+func (sim *Simulation) AnyOf(evs ...Awaitable) Awaitable {
+	ev := sim.Event()
+	result := &CombinedResult{}
+	sim.storeResult(ev, result)
+
+	remaining := len(evs)
+
+	for _, branch := range evs {
+		branch := branch
+
+		if branch.Processed() {
+			result.Processed = append(result.Processed, branch)
+			if !ev.Triggered() {
+				ev.Trigger()
+			}
+
+			continue
+		}
+
+		if branch.Aborted() {
+			result.Aborted = append(result.Aborted, branch)
+			remaining--
+
+			if remaining == 0 && !ev.Triggered() {
+				ev.Abort()
+			}
+
+			continue
+		}
+
+		branch.AddHandler(func(*Event) {
+			result.Processed = append(result.Processed, branch)
+			if !ev.Triggered() {
+				ev.Trigger()
+			}
+		})
+
+		branch.AddAbortHandler(func(*Event) {
+			result.Aborted = append(result.Aborted, branch)
+			remaining--
+
+			if remaining == 0 && !ev.Triggered() {
+				ev.Abort()
+			}
+		})
+	}
+
+	if len(evs) == 0 {
+		ev.Trigger()
+	}
+
+	return ev
+}
+
+// AllOf returns an Awaitable that is processed once every one of evs has
+// been processed, carrying a CombinedResult reachable via sim.Result once
+// triggered. AllOf aborts as soon as any branch aborts.
+func (sim *Simulation) AllOf(evs ...Awaitable) Awaitable {
+	ev := sim.Event()
+	result := &CombinedResult{}
+	sim.storeResult(ev, result)
+
+	remaining := len(evs)
+
+	finish := func() {
+		if remaining == 0 && !ev.Triggered() {
+			ev.Trigger()
+		}
+	}
+
+	for _, branch := range evs {
+		branch := branch
+
+		if branch.Aborted() {
+			result.Aborted = append(result.Aborted, branch)
+			if !ev.Aborted() {
+				ev.Abort()
+			}
+
+			continue
+		}
+
+		if branch.Processed() {
+			result.Processed = append(result.Processed, branch)
+			remaining--
+
+			continue
+		}
+
+		branch.AddHandler(func(*Event) {
+			result.Processed = append(result.Processed, branch)
+			remaining--
+			finish()
+		})
+
+		branch.AddAbortHandler(func(*Event) {
+			result.Aborted = append(result.Aborted, branch)
+			if !ev.Aborted() {
+				ev.Abort()
+			}
+		})
+	}
+
+	finish()
+
+	return ev
+}
+
+// Result returns the CombinedResult recorded for an Awaitable returned by
+// AnyOf or AllOf, reporting which branches were processed and which were
+// aborted. It returns false for any other Awaitable.
+func (sim *Simulation) Result(ev Awaitable) (*CombinedResult, bool) {
+	sim.combinedResultsMu.Lock()
+	defer sim.combinedResultsMu.Unlock()
+
+	result, ok := sim.combinedResults[ev]
+	return result, ok
+}
+
+// storeResult records the CombinedResult for a combinator's Awaitable on the
+// Simulation it belongs to, since Event carries no room for
+// combinator-specific data of its own. Keeping the table on the Simulation
+// instead of a package-level global means two Simulations never share (or
+// race on) each other's combinator bookkeeping.
+func (sim *Simulation) storeResult(ev Awaitable, result *CombinedResult) {
+	sim.combinedResultsMu.Lock()
+	defer sim.combinedResultsMu.Unlock()
+
+	if sim.combinedResults == nil {
+		sim.combinedResults = map[Awaitable]*CombinedResult{}
+	}
+
+	sim.combinedResults[ev] = result
+}
+
+// WithTimeout returns an Awaitable that resolves to whichever of ev and a
+// Timeout(d) fires first. Use sim.Result to tell which branch won: if ev is
+// in Processed, it completed before the timeout; otherwise the timeout won
+// and ev is left running.
+func (sim *Simulation) WithTimeout(ev Awaitable, d float64) Awaitable {
+	return sim.AnyOf(ev, sim.Timeout(d))
+}
@@ -0,0 +1,198 @@
+// Copyright © 2021 Felix Schütz
+// Licensed under the MIT license. See the LICENSE file for details.
+
+package simgo
+
+import "testing"
+
+func TestAnyOfFirstProcessedWins(t *testing.T) {
+	sim := NewSimulation()
+
+	var ok bool
+	var processedAtWake int
+	var resolvedAt float64
+
+	sim.Process(func(proc Process) {
+		fast := proc.Timeout(1)
+		slow := proc.Timeout(10)
+
+		ev := sim.AnyOf(fast, slow)
+		proc.Wait(ev)
+
+		// read Result immediately: the slow branch is still pending at this
+		// point, but it goes on to fire later in its own right (AnyOf
+		// doesn't cancel it), appending itself to the same CombinedResult -
+		// so this has to be captured now, not after sim.Run() returns.
+		var result *CombinedResult
+		result, ok = sim.Result(ev)
+		processedAtWake = len(result.Processed)
+		resolvedAt = proc.Now()
+	})
+
+	sim.Run()
+
+	if !ok {
+		t.Fatal("Result() = false for an AnyOf event, want true")
+	}
+
+	if processedAtWake != 1 {
+		t.Fatalf("Processed at wake-up had %d entries, want exactly the fast branch", processedAtWake)
+	}
+
+	if resolvedAt != 1 {
+		t.Fatalf("resolvedAt = %v, want 1 (AnyOf should not wait for the slow branch)", resolvedAt)
+	}
+}
+
+// TestAnyOfAbortsOnlyWhenEveryBranchAborts is a regression test for AnyOf's
+// abort handling: an aborted branch alone must be ignored (another branch
+// can still win), but if every branch aborts, the combined event aborts too
+// instead of hanging forever.
+func TestAnyOfAbortsOnlyWhenEveryBranchAborts(t *testing.T) {
+	sim := NewSimulation()
+	ch := NewChannel[int](sim, 0)
+	ch.Close()
+
+	// ev is captured outside the process body: Wait kills the process
+	// outright once it sees ev is aborted (the same abort-kills-the-process
+	// behavior Channel.Send documents), so there is no code-after-Wait
+	// inside the process itself to check ev.Aborted() from.
+	var ev Awaitable
+
+	sim.Process(func(proc Process) {
+		ev = sim.AnyOf(ch.SendEvent(1), ch.SendEvent(2))
+		proc.Wait(ev)
+	})
+
+	sim.Run()
+
+	if !ev.Aborted() {
+		t.Fatal("AnyOf did not abort even though every branch aborted")
+	}
+}
+
+func TestAllOfWaitsForEveryBranch(t *testing.T) {
+	sim := NewSimulation()
+
+	var result *CombinedResult
+	var ok bool
+
+	sim.Process(func(proc Process) {
+		a := proc.Timeout(1)
+		b := proc.Timeout(5)
+
+		ev := sim.AllOf(a, b)
+		proc.Wait(ev)
+
+		result, ok = sim.Result(ev)
+	})
+
+	sim.Run()
+
+	if !ok {
+		t.Fatal("Result() = false for an AllOf event, want true")
+	}
+
+	if len(result.Processed) != 2 {
+		t.Fatalf("Processed = %v, want both branches", result.Processed)
+	}
+
+	if sim.Now() != 5 {
+		t.Fatalf("sim.Now() = %v, want 5 (AllOf waits for the slowest branch)", sim.Now())
+	}
+}
+
+// TestAllOfAbortsAsSoonAsOneBranchAborts is a regression test for AllOf:
+// a single aborted branch must abort the combined event immediately,
+// without waiting for the remaining branches.
+func TestAllOfAbortsAsSoonAsOneBranchAborts(t *testing.T) {
+	sim := NewSimulation()
+	ch := NewChannel[int](sim, 0)
+	ch.Close()
+
+	// see TestAnyOfAbortsOnlyWhenEveryBranchAborts for why ev is hoisted out
+	var ev Awaitable
+	// never triggered or aborted on its own, so if AllOf waited for it the
+	// simulation would simply run out of events with ev still pending
+	// instead of aborting
+	pending := sim.Event()
+
+	sim.Process(func(proc Process) {
+		ev = sim.AllOf(ch.SendEvent(1), pending)
+		proc.Wait(ev)
+	})
+
+	sim.Run()
+
+	if !ev.Aborted() {
+		t.Fatal("AllOf did not abort even though a branch aborted")
+	}
+}
+
+func TestWithTimeoutBeforeDeadline(t *testing.T) {
+	sim := NewSimulation()
+	ch := NewChannel[int](sim, 0)
+
+	var ok bool
+	var processedAtWake int
+	var resolvedAt float64
+
+	sim.Process(func(proc Process) {
+		proc.Wait(proc.Timeout(1))
+		ch.Send(proc, 42)
+	})
+
+	sim.Process(func(proc Process) {
+		recv := ch.ReceiveEvent()
+		ev := sim.WithTimeout(recv, 10)
+		proc.Wait(ev)
+
+		// read immediately: the losing Timeout(10) branch is still pending
+		// here but fires later in its own right and appends itself to this
+		// same CombinedResult, so this can't be read after sim.Run().
+		var result *CombinedResult
+		result, ok = sim.Result(ev)
+		processedAtWake = len(result.Processed)
+		resolvedAt = proc.Now()
+	})
+
+	sim.Run()
+
+	if !ok || processedAtWake != 1 {
+		t.Fatalf("Processed at wake-up had %d entries (ok=%v), want exactly the receive", processedAtWake, ok)
+	}
+
+	if resolvedAt != 1 {
+		t.Fatalf("resolvedAt = %v, want 1 (the receive, not the timeout)", resolvedAt)
+	}
+}
+
+// TestWithTimeoutAfterDeadline is a regression test for WithTimeout's other
+// branch: if nothing arrives before d, the timeout wins and the original
+// awaitable is left running (still pending, not aborted).
+func TestWithTimeoutAfterDeadline(t *testing.T) {
+	sim := NewSimulation()
+	ch := NewChannel[int](sim, 0)
+
+	var recv Awaitable
+	var result *CombinedResult
+	var ok bool
+
+	sim.Process(func(proc Process) {
+		recv = ch.ReceiveEvent()
+		ev := sim.WithTimeout(recv, 1)
+		proc.Wait(ev)
+
+		result, ok = sim.Result(ev)
+	})
+
+	sim.Run()
+
+	if !ok || len(result.Processed) != 1 {
+		t.Fatalf("Result() = %v, %v, want the timeout to have won", result, ok)
+	}
+
+	if !recv.Pending() {
+		t.Fatal("the receive should still be pending, not processed or aborted, after losing to the timeout")
+	}
+}
@@ -3,7 +3,10 @@
 
 package simgo
 
-import "runtime"
+import (
+	"runtime"
+	"sync"
+)
 
 // Process is a process in a discrete-event simulation.
 //
@@ -33,14 +36,149 @@ type Process struct {
 	// sync is used to yield to the process / simulation and wait for the
 	// process / simulation.
 	sync chan bool
+
+	// interrupted is used to hand an interrupt over to the process while it
+	// is parked inside Wait, mirroring the sync handoff above.
+	interrupted chan bool
+
+	// interruptAck is used by the process to acknowledge a delivered
+	// interrupt, handing control back to the simulation.
+	interruptAck chan bool
+
+	// interrupts holds interrupts not yet delivered to the process, along
+	// with the most recently delivered one.
+	interrupts *interruptMailbox
+}
+
+// interruptMailbox is the per-process FIFO queue backing Interrupt and
+// Interrupted. It is shared by every copy of a Process value, so it has to
+// be accessed through a pointer.
+type interruptMailbox struct {
+	mu      sync.Mutex
+	queue   []any
+	last    any
+	hasLast bool
+	waiting bool
+}
+
+func newInterruptMailbox() *interruptMailbox {
+	return &interruptMailbox{}
+}
+
+func (m *interruptMailbox) enqueue(value any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.queue = append(m.queue, value)
+}
+
+func (m *interruptMailbox) dequeue() (any, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.queue) == 0 {
+		return nil, false
+	}
+
+	value := m.queue[0]
+	m.queue = m.queue[1:]
+
+	return value, true
+}
+
+func (m *interruptMailbox) deliver(value any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.last = value
+	m.hasLast = true
+}
+
+func (m *interruptMailbox) clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.last = nil
+	m.hasLast = false
+}
+
+func (m *interruptMailbox) delivered() (any, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.last, m.hasLast
+}
+
+// pending reports whether an enqueued interrupt is still waiting to be
+// picked up by Wait's fast path, i.e. whether a scheduled wake-up is still
+// relevant.
+func (m *interruptMailbox) pending() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.queue) > 0
+}
+
+// startWaiting and stopWaiting track whether the process is currently parked
+// in Wait's select, i.e. genuinely ready to receive on proc.interrupted
+// rather than running arbitrary code between two Wait calls. Wait sets this
+// before its "yield to simulation" send, strictly before the select it
+// guards, so once Interrupt's delivery handler observes waiting, the process
+// is guaranteed to reach (or already be in) that select with nothing else
+// able to run in between - letting the handler send on proc.interrupted
+// without racing the process's path from "yielded" to "actually parked".
+func (m *interruptMailbox) startWaiting() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.waiting = true
+}
+
+func (m *interruptMailbox) stopWaiting() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.waiting = false
+}
+
+func (m *interruptMailbox) isWaiting() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.waiting
+}
+
+// trace reports a bookkeeping step for proc to the Simulation's Tracer, if
+// one is installed.
+func (proc Process) trace(kind TraceKind, target Awaitable, payload any) {
+	proc.Simulation.trace(TraceEvent{
+		Time:    proc.Now(),
+		Process: proc.ev.traceIdent(),
+		Event:   traceIdentOf(target),
+		Kind:    kind,
+		Payload: payload,
+	})
 }
 
 // Wait yields from the process to the simulation and waits until the given
 // awaitable is processed.
 //
 // If the awaitable is already processed, the process is not paused. If the
-// awaitable is aborted, the process is aborted too.
+// awaitable is aborted, the process is aborted too. If an interrupt is
+// already queued or arrives while waiting, Wait returns early without the
+// awaitable being processed or aborted; use Interrupted to retrieve the
+// delivered value.
 func (proc Process) Wait(ev Awaitable) {
+	proc.interrupts.clear()
+
+	if value, ok := proc.interrupts.dequeue(); ok {
+		// an interrupt was already queued, deliver it without waiting
+		proc.interrupts.deliver(value)
+		proc.trace(TraceResumed, ev, "interrupt")
+
+		return
+	}
+
 	if ev.Processed() {
 		// event was already processed, do not wait
 		return
@@ -48,12 +186,31 @@ func (proc Process) Wait(ev Awaitable) {
 
 	if ev.Aborted() {
 		// event aborted, abort process
+		proc.trace(TraceAborted, ev, nil)
 		proc.ev.Abort()
 		runtime.Goexit()
 	}
 
+	proc.trace(TraceWaited, ev, nil)
+
+	// resolved is set once this call leaves via the interrupt branch below.
+	// ev can still be triggered or aborted afterwards (an interrupt does not
+	// cancel the awaitable, it just stops this Wait call from waiting on
+	// it), and the handlers registered below would otherwise run at that
+	// later point and try to hand control to this process again - but the
+	// process has already moved on without them, so the handshake below
+	// would send to nobody. Checking resolved first makes that firing a
+	// no-op instead of a deadlock.
+	resolved := false
+
 	// handler called when the event is processed
 	ev.AddHandler(func(*Event) {
+		if resolved {
+			return
+		}
+
+		proc.trace(TraceResumed, ev, nil)
+
 		// yield to process
 		proc.sync <- true
 
@@ -63,6 +220,12 @@ func (proc Process) Wait(ev Awaitable) {
 
 	// handler called when the event is aborted
 	ev.AddAbortHandler(func(*Event) {
+		if resolved {
+			return
+		}
+
+		proc.trace(TraceAborted, ev, nil)
+
 		// abort process
 		proc.sync <- false
 
@@ -70,22 +233,93 @@ func (proc Process) Wait(ev Awaitable) {
 		<-proc.sync
 	})
 
+	// mark ourselves as genuinely parked before yielding, so Interrupt can
+	// tell "about to be in the select below" from "running fn code" (see
+	// interruptMailbox.startWaiting)
+	proc.interrupts.startWaiting()
+
 	// yield to simulation
 	proc.sync <- true
 
 	select {
 	case processed := <-proc.sync: // wait for simulation
+		proc.interrupts.stopWaiting()
+
 		if !processed {
 			// event aborted, abort process
 			proc.ev.Abort()
 			runtime.Goexit()
 		}
 
+	case value := <-proc.interrupted: // wait for an interrupt
+		_ = value
+		proc.interrupts.stopWaiting()
+		resolved = true
+		interruptValue, _ := proc.interrupts.dequeue()
+		proc.interrupts.deliver(interruptValue)
+		proc.trace(TraceResumed, ev, "interrupt")
+		proc.interruptAck <- true
+
 	case <-proc.shutdown: // wait for simulation shutdown
+		proc.interrupts.stopWaiting()
 		runtime.Goexit()
 	}
 }
 
+// Interrupt asynchronously delivers value to proc, causing a call to Wait
+// that is currently blocked to return early with the interrupt delivered
+// instead of the awaitable it was waiting on. If proc is not currently
+// waiting, the interrupt is queued and delivered on the next call to Wait.
+//
+// Multiple pending interrupts are delivered in FIFO order. Delivery itself
+// is scheduled as a regular zero-delay event, so ordering between
+// interrupts and other events stays deterministic with respect to
+// simulated time.
+func (proc Process) Interrupt(value any) {
+	proc.interrupts.enqueue(value)
+
+	wake := proc.Timeout(0)
+	proc.trace(TraceScheduled, wake, value)
+
+	wake.AddHandler(func(*Event) {
+		if !proc.interrupts.pending() {
+			// Wait's fast path already dequeued this (or a later) interrupt
+			// before this wake-up got a chance to run; the process has since
+			// moved on to waiting for something else entirely, so sending on
+			// proc.interrupted now would deliver a phantom interrupt to the
+			// wrong Wait call. Nothing left to do.
+			return
+		}
+
+		if !proc.interrupts.isWaiting() {
+			// process is not currently waiting, the value stays queued
+			return
+		}
+
+		// process is waiting (or, per startWaiting's contract, about to be),
+		// so this send cannot block indefinitely: deliver the interrupt.
+		proc.interrupted <- true
+
+		// wait for the process to acknowledge the interrupt...
+		<-proc.interruptAck
+
+		// ...and then, same as the resume and abort handlers in Wait, wait
+		// for the process to pause again (on its next Wait call, or for
+		// good once fn returns) before handing control back to the
+		// simulation. Without this the process would keep running on its
+		// own goroutine concurrently with the simulation's main loop, which
+		// has already moved on once interruptAck is received.
+		<-proc.sync
+	})
+}
+
+// Interrupted reports whether the most recent call to Wait returned because
+// of an Interrupt rather than because the awaited event was processed or
+// aborted, returning the delivered value and true if so.
+func (proc Process) Interrupted() (any, bool) {
+	return proc.interrupts.delivered()
+}
+
 // Pending returns whether the underlying event is pending.
 func (proc Process) Pending() bool {
 	return proc.ev.Pending()
@@ -0,0 +1,117 @@
+// Copyright © 2021 Felix Schütz
+// Licensed under the MIT license. See the LICENSE file for details.
+
+package simgo
+
+import "testing"
+
+func TestInterruptWhileWaiting(t *testing.T) {
+	sim := NewSimulation()
+
+	var value any
+	var interrupted bool
+	var resumed bool
+	var resumedAt float64
+
+	target := sim.Process(func(proc Process) {
+		proc.Wait(proc.Timeout(10))
+		value, interrupted = proc.Interrupted()
+		resumed = true
+		resumedAt = proc.Now()
+	})
+
+	sim.Process(func(proc Process) {
+		proc.Wait(proc.Timeout(1))
+		target.Interrupt("evicted")
+	})
+
+	sim.Run()
+
+	if !interrupted {
+		t.Fatal("Interrupted() = false, want true after Interrupt fired while waiting")
+	}
+
+	if value != "evicted" {
+		t.Fatalf("Interrupted() value = %v, want %q", value, "evicted")
+	}
+
+	if !resumed {
+		t.Fatal("target process never reached the code after Wait, meaning Interrupt did not resume it")
+	}
+
+	if resumedAt != 1 {
+		t.Fatalf("target resumed at %v, want 1 (the interrupt's time, not the abandoned timeout's)", resumedAt)
+	}
+}
+
+// TestInterruptQueuedBeforeWait is a regression check for the case where
+// Interrupt is delivered before the target ever calls Wait: the interrupt
+// must queue and be picked up by the next Wait call instead of being lost or
+// blocking the simulation forever.
+func TestInterruptQueuedBeforeWait(t *testing.T) {
+	sim := NewSimulation()
+
+	var value any
+	var interrupted bool
+
+	// the interrupter is started first, so it runs (and interrupts) before
+	// the target's own body has run at all.
+	var target Process
+
+	sim.Process(func(proc Process) {
+		// no Wait first: interrupt the target before it has even started.
+		target.Interrupt("early")
+	})
+
+	target = sim.Process(func(proc Process) {
+		proc.Wait(proc.Timeout(5))
+		value, interrupted = proc.Interrupted()
+	})
+
+	sim.Run()
+
+	if !interrupted {
+		t.Fatal("Interrupted() = false, want true for an interrupt queued before Wait was called")
+	}
+
+	if value != "early" {
+		t.Fatalf("Interrupted() value = %v, want %q", value, "early")
+	}
+}
+
+// TestInterruptThenWaitAgain is a regression test for the handshake between
+// Interrupt and Wait: after being interrupted out of one Wait call, the
+// process must still be able to Wait on a later event without the simulation
+// deadlocking, even though the originally awaited event (the long Timeout)
+// is still scheduled and will eventually fire on its own.
+func TestInterruptThenWaitAgain(t *testing.T) {
+	sim := NewSimulation()
+
+	var resumedAfterSecondWait bool
+
+	target := sim.Process(func(proc Process) {
+		proc.Wait(proc.Timeout(100))
+
+		if _, ok := proc.Interrupted(); !ok {
+			t.Error("Interrupted() = false, want true")
+		}
+
+		proc.Wait(proc.Timeout(1))
+		resumedAfterSecondWait = true
+	})
+
+	sim.Process(func(proc Process) {
+		proc.Wait(proc.Timeout(1))
+		target.Interrupt("evicted")
+	})
+
+	sim.Run()
+
+	if !resumedAfterSecondWait {
+		t.Fatal("target never resumed from the Wait call made after being interrupted")
+	}
+
+	if sim.Now() != 100 {
+		t.Fatalf("sim.Now() = %v, want 100 (the abandoned long Timeout still fires on its own)", sim.Now())
+	}
+}
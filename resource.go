@@ -0,0 +1,408 @@
+// Copyright © 2021 Felix Schütz
+// Licensed under the MIT license. See the LICENSE file for details.
+
+package simgo
+
+// Resource is a counting resource with a fixed number of slots. Processes
+// acquire a slot with Request and give it back with Release; requests beyond
+// the available slots queue in FIFO order until one frees up.
+//
+// Use NewResource to create a Resource:
+//
+//	res := simgo.NewResource(sim, 2)
+//	sim.Process(func(proc simgo.Process) {
+//	    proc.Wait(res.Request())
+//	    defer res.Release()
+//	    proc.Wait(proc.Timeout(5))
+//	})
+type Resource struct {
+	sim *Simulation
+
+	capacity int
+	inUse    int
+
+	waiters []*Event
+}
+
+// NewResource creates a Resource with the given number of slots.
+func NewResource(sim *Simulation, capacity int) *Resource {
+	return &Resource{sim: sim, capacity: capacity}
+}
+
+// Request returns an Awaitable that is processed once a slot is available.
+// Every processed Request must eventually be matched by a Release.
+func (res *Resource) Request() Awaitable {
+	ev := res.sim.Event()
+
+	if res.inUse < res.capacity {
+		res.inUse++
+		ev.Trigger()
+
+		return ev
+	}
+
+	res.waiters = append(res.waiters, ev)
+	res.sim.traceScheduled(ev, "resource request")
+
+	return ev
+}
+
+// Release gives a slot back to the resource, waking the longest-waiting
+// queued Request, if any, at the current simulated time.
+func (res *Resource) Release() {
+	if len(res.waiters) > 0 {
+		waiter := res.waiters[0]
+		res.waiters = res.waiters[1:]
+		waiter.Trigger()
+
+		return
+	}
+
+	res.inUse--
+}
+
+// InUse returns the number of slots currently taken.
+func (res *Resource) InUse() int {
+	return res.inUse
+}
+
+// Capacity returns the total number of slots.
+func (res *Resource) Capacity() int {
+	return res.capacity
+}
+
+// PreemptiveResource is a Resource whose slots can be reclaimed from a
+// holder before it releases them. Preempting a slot delivers an Interrupt
+// carrying the value passed to Preempt to the evicted holder, so the holder can
+// react the same way it would to any other interrupt.
+type PreemptiveResource struct {
+	Resource
+
+	holders []Process
+}
+
+// NewPreemptiveResource creates a PreemptiveResource with the given number
+// of slots.
+func NewPreemptiveResource(sim *Simulation, capacity int) *PreemptiveResource {
+	return &PreemptiveResource{Resource: Resource{sim: sim, capacity: capacity}}
+}
+
+// Request behaves like Resource.Request, additionally remembering the
+// requesting process so its slot can later be preempted.
+func (res *PreemptiveResource) Request(proc Process) Awaitable {
+	ev := res.Resource.Request()
+
+	if ev.Processed() {
+		res.holders = append(res.holders, proc)
+	} else {
+		ev.AddHandler(func(*Event) {
+			res.holders = append(res.holders, proc)
+		})
+	}
+
+	return ev
+}
+
+// Release gives back the slot held by proc, removing it from the set of
+// current holders before handing the slot to the next waiter. Calling
+// Resource.Release (inherited via embedding) directly on a PreemptiveResource
+// would leave a stale entry in holders, so every Release on a
+// PreemptiveResource must go through this override instead.
+//
+// If proc is not a current holder, Release is a no-op: this is what lets a
+// holder that was preempted out from under it (Preempt already removed it
+// from holders and released its slot) keep calling Release unconditionally,
+// the same way it would for a plain Resource, without double-releasing the
+// slot.
+func (res *PreemptiveResource) Release(proc Process) {
+	for i, holder := range res.holders {
+		if holder == proc {
+			res.holders = append(res.holders[:i], res.holders[i+1:]...)
+			res.Resource.Release()
+
+			return
+		}
+	}
+}
+
+// Preempt reclaims the slot held the longest, delivering value to its
+// holder via Interrupt and freeing the slot for the next waiter. Preempt is
+// a no-op if no slot is currently held. Preempt removes the evicted holder
+// from holders itself, so a later Release by the evicted holder is a no-op
+// and does not free the slot a second time.
+func (res *PreemptiveResource) Preempt(value any) {
+	if len(res.holders) == 0 {
+		return
+	}
+
+	holder := res.holders[0]
+	res.holders = res.holders[1:]
+	holder.Interrupt(value)
+	res.Resource.Release()
+}
+
+// Container models a bulk quantity, such as fuel in a tank. Put and Get
+// block until the requested amount is free or available, respectively.
+//
+// Use NewContainer to create a Container:
+//
+//	tank := simgo.NewContainer(sim, 100, 100)
+type Container struct {
+	sim *Simulation
+
+	capacity float64
+	level    float64
+
+	putWaiters []containerWaiter
+	getWaiters []containerWaiter
+}
+
+type containerWaiter struct {
+	amount float64
+	ev     *Event
+}
+
+// NewContainer creates a Container with the given capacity and initial
+// level.
+func NewContainer(sim *Simulation, capacity, level float64) *Container {
+	return &Container{sim: sim, capacity: capacity, level: level}
+}
+
+// Put returns an Awaitable that is processed once amount can be added
+// without exceeding the container's capacity.
+func (c *Container) Put(amount float64) Awaitable {
+	ev := c.sim.Event()
+
+	if c.level+amount <= c.capacity {
+		c.level += amount
+		c.wakeGetters()
+		ev.Trigger()
+
+		return ev
+	}
+
+	c.putWaiters = append(c.putWaiters, containerWaiter{amount: amount, ev: ev})
+	c.sim.traceScheduled(ev, "container put")
+
+	return ev
+}
+
+// Get returns an Awaitable that is processed once amount is available to
+// remove from the container.
+func (c *Container) Get(amount float64) Awaitable {
+	ev := c.sim.Event()
+
+	if c.level >= amount {
+		c.level -= amount
+		c.wakePutters()
+		ev.Trigger()
+
+		return ev
+	}
+
+	c.getWaiters = append(c.getWaiters, containerWaiter{amount: amount, ev: ev})
+	c.sim.traceScheduled(ev, "container get")
+
+	return ev
+}
+
+// Level returns the amount currently held in the container.
+func (c *Container) Level() float64 {
+	return c.level
+}
+
+// wakeGetters wakes every queued Get that the current level can satisfy, in
+// FIFO order. Draining a Get frees up room for queued Puts, so it also
+// re-checks wakePutters once it has made progress, the same way a manual
+// Put/Get pair interleaved at this instant would.
+func (c *Container) wakeGetters() {
+	woke := false
+
+	for len(c.getWaiters) > 0 {
+		waiter := c.getWaiters[0]
+		if c.level < waiter.amount {
+			break
+		}
+
+		c.getWaiters = c.getWaiters[1:]
+		c.level -= waiter.amount
+		waiter.ev.Trigger()
+		woke = true
+	}
+
+	if woke {
+		c.wakePutters()
+	}
+}
+
+// wakePutters wakes every queued Put that the current level leaves room for,
+// in FIFO order, then re-checks wakeGetters once it has made progress, for
+// the same reason wakeGetters re-checks wakePutters.
+func (c *Container) wakePutters() {
+	woke := false
+
+	for len(c.putWaiters) > 0 {
+		waiter := c.putWaiters[0]
+		if c.level+waiter.amount > c.capacity {
+			break
+		}
+
+		c.putWaiters = c.putWaiters[1:]
+		c.level += waiter.amount
+		waiter.ev.Trigger()
+		woke = true
+	}
+
+	if woke {
+		c.wakeGetters()
+	}
+}
+
+// Store is a typed FIFO queue of items. Put and Get block until there is
+// room for an item and until a matching item is available, respectively.
+//
+// Use NewStore to create a Store:
+//
+//	store := simgo.NewStore[string](sim, 10)
+type Store[T any] struct {
+	sim *Simulation
+
+	capacity int
+	items    []T
+
+	putWaiters []*storePutWaiter[T]
+	getWaiters []storeGetWaiter[T]
+}
+
+type storePutWaiter[T any] struct {
+	item T
+	ev   *Event
+}
+
+type storeGetWaiter[T any] struct {
+	filter func(T) bool
+	result *T
+	ev     *Event
+}
+
+// NewStore creates a Store that holds up to capacity items.
+func NewStore[T any](sim *Simulation, capacity int) *Store[T] {
+	return &Store[T]{sim: sim, capacity: capacity}
+}
+
+// Put returns an Awaitable that is processed once item has been added to
+// the store, which may block if the store is at capacity.
+func (s *Store[T]) Put(item T) Awaitable {
+	ev := s.sim.Event()
+
+	if len(s.items) >= s.capacity {
+		s.putWaiters = append(s.putWaiters, &storePutWaiter[T]{item: item, ev: ev})
+		s.sim.traceScheduled(ev, "store put")
+
+		return ev
+	}
+
+	s.items = append(s.items, item)
+	s.wakeGetters()
+	ev.Trigger()
+
+	return ev
+}
+
+// Get returns an Awaitable that is processed once a matching item is
+// available, and makes that item available via its Value method once
+// processed.
+// A nil filter matches any item.
+func (s *Store[T]) Get(filter func(T) bool) Awaitable {
+	if filter == nil {
+		filter = func(T) bool { return true }
+	}
+
+	for i, item := range s.items {
+		if !filter(item) {
+			continue
+		}
+
+		s.items = append(s.items[:i], s.items[i+1:]...)
+		s.wakePutters()
+
+		ev := s.sim.Event()
+		ev.Trigger()
+
+		return &storeGetEvent[T]{Event: ev, value: item}
+	}
+
+	result := new(T)
+	ev := s.sim.Event()
+	s.getWaiters = append(s.getWaiters, storeGetWaiter[T]{filter: filter, result: result, ev: ev})
+	s.sim.traceScheduled(ev, "store get")
+
+	return &storeGetEvent[T]{Event: ev, valuePtr: result}
+}
+
+// storeGetEvent carries the item a Get call resolves to alongside the
+// underlying Event, the same way channelReceiveEvent does for Channel.
+type storeGetEvent[T any] struct {
+	*Event
+	value    T
+	valuePtr *T
+}
+
+// Value returns the item delivered by a processed Get event.
+func (e *storeGetEvent[T]) Value() T {
+	if e.valuePtr != nil {
+		return *e.valuePtr
+	}
+
+	return e.value
+}
+
+// wakeGetters wakes every queued Get whose filter matches an item currently
+// in the store, in FIFO order. Draining an item frees up room for queued
+// Puts, so it also re-checks wakePutters once it has made progress, the
+// same way a manual Put/Get pair interleaved at this instant would.
+func (s *Store[T]) wakeGetters() {
+	woke := false
+
+	for i := 0; i < len(s.getWaiters); i++ {
+		waiter := s.getWaiters[i]
+
+		for j, item := range s.items {
+			if !waiter.filter(item) {
+				continue
+			}
+
+			s.items = append(s.items[:j], s.items[j+1:]...)
+			*waiter.result = item
+			waiter.ev.Trigger()
+			s.getWaiters = append(s.getWaiters[:i], s.getWaiters[i+1:]...)
+			i--
+			woke = true
+
+			break
+		}
+	}
+
+	if woke {
+		s.wakePutters()
+	}
+}
+
+// wakePutters wakes every queued Put that the current item count leaves
+// room for, in FIFO order, then re-checks wakeGetters once it has made
+// progress, for the same reason wakeGetters re-checks wakePutters.
+func (s *Store[T]) wakePutters() {
+	woke := false
+
+	for len(s.putWaiters) > 0 && len(s.items) < s.capacity {
+		waiter := s.putWaiters[0]
+		s.putWaiters = s.putWaiters[1:]
+		s.items = append(s.items, waiter.item)
+		waiter.ev.Trigger()
+		woke = true
+	}
+
+	if woke {
+		s.wakeGetters()
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright © 2021 Felix Schütz
+// Licensed under the MIT license. See the LICENSE file for details.
+
+package simgo
+
+import "testing"
+
+func TestResourceRequestRelease(t *testing.T) {
+	sim := NewSimulation()
+	res := NewResource(sim, 1)
+
+	var firstInUse, secondStarted bool
+
+	sim.Process(func(proc Process) {
+		proc.Wait(res.Request())
+		firstInUse = true
+		proc.Wait(proc.Timeout(1))
+		res.Release()
+	})
+
+	sim.Process(func(proc Process) {
+		proc.Wait(res.Request())
+		secondStarted = true
+		res.Release()
+	})
+
+	sim.Run()
+
+	if !firstInUse || !secondStarted {
+		t.Fatalf("firstInUse = %v, secondStarted = %v, want both true", firstInUse, secondStarted)
+	}
+
+	if got := res.InUse(); got != 0 {
+		t.Fatalf("InUse() = %v after both requests released, want 0", got)
+	}
+}
+
+// TestPreemptiveResourceReleaseAfterPreempt is a regression test for a
+// double-release: Preempt already removes the evicted holder from holders
+// and frees its slot, so a holder that (carelessly, or via a plain defer
+// res.Release(proc) that doesn't check Interrupted first) still calls
+// Release after being preempted must not free the slot a second time.
+func TestPreemptiveResourceReleaseAfterPreempt(t *testing.T) {
+	sim := NewSimulation()
+	res := NewPreemptiveResource(sim, 1)
+
+	sim.Process(func(proc Process) {
+		proc.Wait(res.Request(proc))
+		proc.Wait(proc.Timeout(10))
+		// careless: releases unconditionally, without checking whether it
+		// was preempted out from under it first.
+		res.Release(proc)
+	})
+
+	sim.Process(func(proc Process) {
+		proc.Wait(proc.Timeout(1))
+		res.Preempt("evicted")
+	})
+
+	var requesterAAdmitted, requesterBAdmitted bool
+
+	sim.Process(func(proc Process) {
+		proc.Wait(proc.Timeout(2))
+		proc.Wait(res.Request(proc))
+		requesterAAdmitted = true
+	})
+
+	sim.Process(func(proc Process) {
+		proc.Wait(proc.Timeout(2))
+		proc.Wait(res.Request(proc))
+		requesterBAdmitted = true
+	})
+
+	sim.Run()
+
+	if !requesterAAdmitted {
+		t.Fatal("requester A was never admitted after the preempted slot freed up")
+	}
+
+	if requesterBAdmitted {
+		t.Fatal("requester B was admitted past capacity 1: the preempted holder's own Release double-released the slot")
+	}
+
+	if got := res.InUse(); got != 1 {
+		t.Fatalf("InUse() = %v, want 1 (only requester A holds the slot)", got)
+	}
+}
+
+func TestContainerPutGet(t *testing.T) {
+	sim := NewSimulation()
+	tank := NewContainer(sim, 10, 0)
+
+	sim.Process(func(proc Process) {
+		proc.Wait(tank.Put(10))
+	})
+
+	var got float64
+	sim.Process(func(proc Process) {
+		proc.Wait(tank.Get(4))
+		got = tank.Level()
+	})
+
+	sim.Run()
+
+	if got != 6 {
+		t.Fatalf("level after Get(4) = %v, want 6", got)
+	}
+}
+
+// TestContainerWakeCascade is a regression test for a deadlock where a Put
+// that only becomes satisfiable as a side effect of a Get's wakeGetters
+// cascade (rather than directly) was never woken.
+func TestContainerWakeCascade(t *testing.T) {
+	sim := NewSimulation()
+	tank := NewContainer(sim, 10, 8)
+
+	putWoken := false
+	sim.Process(func(proc Process) {
+		proc.Wait(tank.Put(5)) // 8+5 > 10, queues
+		putWoken = true
+	})
+
+	getWoken := false
+	sim.Process(func(proc Process) {
+		proc.Wait(tank.Get(9)) // 8 < 9, queues
+		getWoken = true
+	})
+
+	sim.Process(func(proc Process) {
+		// 8+1 <= 10, succeeds immediately and drains the queued Get, which
+		// drops the level to 0 and should in turn let the queued Put in.
+		proc.Wait(tank.Put(1))
+	})
+
+	sim.Run()
+
+	if !getWoken {
+		t.Fatal("queued Get(9) was never woken")
+	}
+
+	if !putWoken {
+		t.Fatal("queued Put(5) was never woken by the Get(9) cascade")
+	}
+
+	if got := tank.Level(); got != 5 {
+		t.Fatalf("level = %v, want 5", got)
+	}
+}
+
+func TestStorePutGet(t *testing.T) {
+	sim := NewSimulation()
+	store := NewStore[string](sim, 1)
+
+	sim.Process(func(proc Process) {
+		proc.Wait(store.Put("a"))
+	})
+
+	var got string
+	sim.Process(func(proc Process) {
+		ev := store.Get(nil).(*storeGetEvent[string])
+		proc.Wait(ev)
+		got = ev.Value()
+	})
+
+	sim.Run()
+
+	if got != "a" {
+		t.Fatalf("Get(nil) = %q, want %q", got, "a")
+	}
+}
+
+// TestStoreWakeCascade is a regression test for a deadlock where a filtered
+// Get that only becomes satisfiable as a side effect of wakePutters placing
+// the matching item into the store (rather than directly) was never woken.
+func TestStoreWakeCascade(t *testing.T) {
+	sim := NewSimulation()
+	store := NewStore[string](sim, 1)
+
+	sim.Process(func(proc Process) {
+		proc.Wait(store.Put("a")) // fills the store
+	})
+
+	bWoken := false
+	var gotB string
+	sim.Process(func(proc Process) {
+		ev := store.Get(func(v string) bool { return v == "b" }).(*storeGetEvent[string])
+		proc.Wait(ev) // "b" is not in the store yet, queues
+		bWoken = true
+		gotB = ev.Value()
+	})
+
+	sim.Process(func(proc Process) {
+		proc.Wait(store.Put("b")) // store is full, queues
+	})
+
+	sim.Process(func(proc Process) {
+		ev := store.Get(func(v string) bool { return v == "a" }).(*storeGetEvent[string])
+		// draining "a" frees room for the queued Put("b"), which should in
+		// turn wake the Get waiting on "b".
+		proc.Wait(ev)
+	})
+
+	sim.Run()
+
+	if !bWoken {
+		t.Fatal("filtered Get(\"b\") was never woken by the Put(\"b\") cascade")
+	}
+
+	if gotB != "b" {
+		t.Fatalf("Get(\"b\") = %q, want %q", gotB, "b")
+	}
+}
@@ -0,0 +1,375 @@
+// Copyright © 2021 Felix Schütz
+// Licensed under the MIT license. See the LICENSE file for details.
+
+package simgo
+
+import (
+	"container/heap"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Handler is called when an Event is processed or aborted.
+type Handler func(*Event)
+
+// Awaitable is anything Process.Wait can wait on: an *Event itself, or a
+// type that wraps one to carry extra data alongside it (see
+// channelReceiveEvent and storeGetEvent).
+type Awaitable interface {
+	// Pending reports whether the event is neither triggered nor aborted
+	// yet.
+	Pending() bool
+
+	// Triggered reports whether the event has been scheduled to be
+	// processed, whether or not the simulation has reached that point yet.
+	Triggered() bool
+
+	// Processed reports whether the simulation has run the event's
+	// handlers.
+	Processed() bool
+
+	// Aborted reports whether the event was aborted instead of processed.
+	Aborted() bool
+
+	// AddHandler registers handler to run once the event is processed. If
+	// the event is already processed, handler runs immediately.
+	AddHandler(Handler)
+
+	// AddAbortHandler registers handler to run if the event is aborted. If
+	// the event is already aborted, handler runs immediately.
+	AddAbortHandler(Handler)
+}
+
+// Event is the basic unit of a Simulation: something that happens, once, at
+// a single point in simulated time, either by being processed or by being
+// aborted.
+//
+// Create one with (*Simulation).Event, or indirectly via (*Simulation).Timeout.
+type Event struct {
+	sim *Simulation
+
+	// id is the order in which ev was created relative to every other
+	// Event in the same Simulation, used as a stable trace identity - see
+	// traceIdent.
+	id uint64
+
+	triggered bool
+	processed bool
+	aborted   bool
+
+	handlers      []Handler
+	abortHandlers []Handler
+}
+
+// Pending implements Awaitable.
+func (ev *Event) Pending() bool {
+	return !ev.triggered && !ev.aborted
+}
+
+// Triggered implements Awaitable.
+func (ev *Event) Triggered() bool {
+	return ev.triggered
+}
+
+// Processed implements Awaitable.
+func (ev *Event) Processed() bool {
+	return ev.processed
+}
+
+// Aborted implements Awaitable.
+func (ev *Event) Aborted() bool {
+	return ev.aborted
+}
+
+// AddHandler implements Awaitable.
+func (ev *Event) AddHandler(handler Handler) {
+	if ev.processed {
+		handler(ev)
+		return
+	}
+
+	if ev.aborted {
+		return
+	}
+
+	ev.handlers = append(ev.handlers, handler)
+}
+
+// AddAbortHandler implements Awaitable.
+func (ev *Event) AddAbortHandler(handler Handler) {
+	if ev.aborted {
+		handler(ev)
+		return
+	}
+
+	if ev.processed {
+		return
+	}
+
+	ev.abortHandlers = append(ev.abortHandlers, handler)
+}
+
+// Trigger schedules ev to be processed at the current simulated time. It is
+// a no-op if ev is already triggered or aborted.
+func (ev *Event) Trigger() {
+	ev.triggerAt(ev.sim.now)
+}
+
+// triggerAt schedules ev to be processed at the given simulated time. It is
+// a no-op if ev is already triggered or aborted.
+func (ev *Event) triggerAt(at float64) {
+	if ev.triggered || ev.aborted {
+		return
+	}
+
+	ev.triggered = true
+	ev.sim.schedule(ev, at)
+}
+
+// traceIdent returns a stable identity for ev to use in trace output. It is
+// based on creation order rather than ev's memory address, so two
+// deterministic runs that create events in the same order produce matching
+// identities - see Replay, which depends on this.
+func (ev *Event) traceIdent() string {
+	return fmt.Sprintf("ev%d", ev.id)
+}
+
+// Abort marks ev as aborted instead of processed, running its abort
+// handlers immediately. It is a no-op if ev is already triggered or
+// aborted.
+func (ev *Event) Abort() {
+	if ev.triggered || ev.aborted {
+		return
+	}
+
+	ev.aborted = true
+
+	handlers := ev.abortHandlers
+	ev.abortHandlers = nil
+
+	for _, handler := range handlers {
+		handler(ev)
+	}
+}
+
+// scheduledEvent pairs an Event with the simulated time it is due and a
+// sequence number, so events scheduled for the same instant are still
+// processed in the order they were scheduled.
+type scheduledEvent struct {
+	time float64
+	seq  uint64
+	ev   *Event
+}
+
+// eventQueue is a min-heap of scheduledEvent ordered by (time, seq),
+// implementing container/heap.Interface.
+type eventQueue []*scheduledEvent
+
+func (q eventQueue) Len() int { return len(q) }
+
+func (q eventQueue) Less(i, j int) bool {
+	if q[i].time != q[j].time {
+		return q[i].time < q[j].time
+	}
+
+	return q[i].seq < q[j].seq
+}
+
+func (q eventQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *eventQueue) Push(x any) {
+	*q = append(*q, x.(*scheduledEvent))
+}
+
+func (q *eventQueue) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+
+	return item
+}
+
+// Simulation is a single discrete-event simulation run: a clock, a queue of
+// events ordered by the simulated time they are due, and the processes
+// running against it.
+//
+// Use NewSimulation to create one, sim.Process (or sim.ProcessReflect) to
+// start processes against it, and sim.Run (or sim.RunUntil) to drive it.
+type Simulation struct {
+	now   float64
+	queue eventQueue
+	seq   uint64
+
+	// shutdown is closed once Run/RunUntil stops driving the simulation, so
+	// that any process still parked in Wait can give up instead of blocking
+	// forever.
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+
+	tracer   Tracer
+	traceSeq uint64
+
+	combinedResults   map[Awaitable]*CombinedResult
+	combinedResultsMu sync.Mutex
+
+	// eventSeq assigns each Event its id, in creation order.
+	eventSeq uint64
+}
+
+// NewSimulation creates a Simulation with its clock at 0.
+func NewSimulation() *Simulation {
+	return &Simulation{shutdown: make(chan struct{})}
+}
+
+// Now returns the current simulated time.
+func (sim *Simulation) Now() float64 {
+	return sim.now
+}
+
+// Event creates a new, untriggered Event belonging to sim.
+func (sim *Simulation) Event() *Event {
+	sim.eventSeq++
+	return &Event{sim: sim, id: sim.eventSeq}
+}
+
+// Timeout returns an Awaitable that is processed once d simulated time units
+// have passed. A negative d panics, matching there being no such thing as a
+// timeout into the past.
+func (sim *Simulation) Timeout(d float64) *Event {
+	if d < 0 {
+		panic("simgo: negative timeout")
+	}
+
+	ev := sim.Event()
+	ev.triggerAt(sim.now + d)
+
+	return ev
+}
+
+// schedule queues ev to be processed once the simulation's clock reaches at.
+func (sim *Simulation) schedule(ev *Event, at float64) {
+	sim.seq++
+	heap.Push(&sim.queue, &scheduledEvent{time: at, seq: sim.seq, ev: ev})
+}
+
+// Process starts fn as a new process against sim, running it on its own
+// goroutine in lockstep with the simulation: fn only ever runs between two
+// calls to Process.Wait (or before the first / after the last), never
+// concurrently with the simulation's own bookkeeping.
+func (sim *Simulation) Process(fn func(Process)) Process {
+	proc := newProcess(sim)
+
+	go func() {
+		defer func() {
+			if !proc.ev.processed && !proc.ev.aborted {
+				proc.ev.Trigger()
+			}
+
+			select {
+			case proc.sync <- true: // hand control back to the simulation
+			case <-sim.shutdown: // nobody is left to hand control back to
+			}
+		}()
+
+		<-proc.sync // wait for the simulation to start us
+		fn(proc)
+	}()
+
+	start := sim.Event()
+	start.AddHandler(func(*Event) {
+		proc.trace(TraceStarted, proc.ev, nil)
+
+		proc.sync <- true // yield to process
+		<-proc.sync       // wait for process
+	})
+	start.Trigger()
+
+	return proc
+}
+
+// ProcessReflect starts a process the same way Process does, calling fn via
+// reflection with a leading Process argument followed by args. This lets
+// process bodies that need more than a Process parameter be started the
+// same way, at the cost of fn's signature being checked at run time instead
+// of compile time.
+func (sim *Simulation) ProcessReflect(fn any, args ...any) Process {
+	fnValue := reflect.ValueOf(fn)
+
+	callArgs := make([]reflect.Value, 0, len(args)+1)
+
+	return sim.Process(func(proc Process) {
+		callArgs = callArgs[:0]
+		callArgs = append(callArgs, reflect.ValueOf(proc))
+
+		for _, arg := range args {
+			callArgs = append(callArgs, reflect.ValueOf(arg))
+		}
+
+		fnValue.Call(callArgs)
+	})
+}
+
+// step pops and processes the single earliest-due event in the queue,
+// advancing the simulated clock to its time. It reports whether there was
+// an event to process.
+func (sim *Simulation) step() bool {
+	if len(sim.queue) == 0 {
+		return false
+	}
+
+	item := heap.Pop(&sim.queue).(*scheduledEvent)
+	sim.now = item.time
+
+	ev := item.ev
+	if ev.aborted {
+		return true
+	}
+
+	ev.processed = true
+	handlers := ev.handlers
+	ev.handlers = nil
+
+	for _, handler := range handlers {
+		handler(ev)
+	}
+
+	return true
+}
+
+// Run drives the simulation until no more events are scheduled, then
+// releases any process still parked in Wait.
+func (sim *Simulation) Run() {
+	for sim.step() {
+	}
+
+	sim.shutdownOnce.Do(func() { close(sim.shutdown) })
+}
+
+// RunUntil drives the simulation until its clock would pass until. Unlike
+// Run, it does not shut the simulation down, so a later call to RunUntil or
+// Run picks up where this one left off.
+func (sim *Simulation) RunUntil(until float64) {
+	for len(sim.queue) > 0 && sim.queue[0].time <= until {
+		sim.step()
+	}
+
+	if sim.now < until {
+		sim.now = until
+	}
+}
+
+// newProcess creates a Process bound to sim, with its own completion event
+// and the channels/mailbox Wait and Interrupt need.
+func newProcess(sim *Simulation) Process {
+	return Process{
+		Simulation:   sim,
+		ev:           sim.Event(),
+		sync:         make(chan bool),
+		interrupted:  make(chan bool),
+		interruptAck: make(chan bool),
+		interrupts:   newInterruptMailbox(),
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright © 2021 Felix Schütz
+// Licensed under the MIT license. See the LICENSE file for details.
+
+package simgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// TraceKind identifies the kind of bookkeeping a TraceEvent records.
+type TraceKind string
+
+const (
+	TraceScheduled TraceKind = "scheduled"
+	TraceStarted   TraceKind = "started"
+	TraceWaited    TraceKind = "waited"
+	TraceResumed   TraceKind = "resumed"
+	TraceAborted   TraceKind = "aborted"
+)
+
+// TraceEvent is a single recorded step of a simulation run, timestamped by
+// simulated time and tagged with a monotonically increasing sequence number
+// so steps that share a simulated time still have a stable order.
+type TraceEvent struct {
+	Seq     uint64    `json:"seq"`
+	Time    float64   `json:"time"`
+	Process string    `json:"process,omitempty"`
+	Event   string    `json:"event,omitempty"`
+	Kind    TraceKind `json:"kind"`
+	Payload any       `json:"payload,omitempty"`
+}
+
+// Tracer receives a TraceEvent for every process start, wait, resume and
+// abort a Simulation performs. TraceScheduled is reported only for events
+// that queue behind a Channel, Resource, Container or Store and for
+// Interrupt wake-ups; events scheduled by Trigger or Timeout alone are not
+// reported. SetTracer installs one.
+type Tracer interface {
+	Trace(TraceEvent)
+}
+
+// SetTracer installs t as the Simulation's tracer. Pass nil to stop
+// tracing.
+func (sim *Simulation) SetTracer(t Tracer) {
+	sim.tracer = t
+}
+
+// trace forwards ev to the installed Tracer, if any, stamping it with the
+// next sequence number.
+func (sim *Simulation) trace(ev TraceEvent) {
+	if sim.tracer == nil {
+		return
+	}
+
+	sim.traceSeq++
+	ev.Seq = sim.traceSeq
+	sim.tracer.Trace(ev)
+}
+
+// traceScheduled reports that target was scheduled, for call sites such as
+// Resource, Container and Store that create events without a Process in
+// hand to attribute them to.
+func (sim *Simulation) traceScheduled(target Awaitable, payload any) {
+	sim.trace(TraceEvent{
+		Time:    sim.Now(),
+		Event:   traceIdentOf(target),
+		Kind:    TraceScheduled,
+		Payload: payload,
+	})
+}
+
+// identifiable is implemented by Awaitables that can report their own
+// traceIdent, which *Event does and every wrapper around one (such as
+// channelReceiveEvent) inherits by embedding it.
+type identifiable interface {
+	traceIdent() string
+}
+
+// traceIdentOf returns a's trace identity, falling back to its pointer
+// address for an Awaitable that isn't identifiable.
+func traceIdentOf(a Awaitable) string {
+	if ev, ok := a.(identifiable); ok {
+		return ev.traceIdent()
+	}
+
+	return fmt.Sprintf("%p", a)
+}
+
+// JSONLTracer is a Tracer that writes one JSON object per line to w,
+// suitable for diffing or replaying later.
+type JSONLTracer struct {
+	w   io.Writer
+	err error
+}
+
+// NewJSONLTracer creates a JSONLTracer writing to w.
+func NewJSONLTracer(w io.Writer) *JSONLTracer {
+	return &JSONLTracer{w: w}
+}
+
+// Trace implements Tracer.
+func (t *JSONLTracer) Trace(ev TraceEvent) {
+	if t.err != nil {
+		return
+	}
+
+	line, err := json.Marshal(ev)
+	if err != nil {
+		t.err = err
+		return
+	}
+
+	line = append(line, '\n')
+	if _, err := t.w.Write(line); err != nil {
+		t.err = err
+	}
+}
+
+// Err returns the first error encountered while writing the trace, if any.
+func (t *JSONLTracer) Err() error {
+	return t.err
+}
+
+// ReadTrace decodes a sequence of TraceEvents previously written by a
+// JSONLTracer.
+func ReadTrace(r io.Reader) ([]TraceEvent, error) {
+	dec := json.NewDecoder(r)
+
+	var trace []TraceEvent
+	for dec.More() {
+		var ev TraceEvent
+		if err := dec.Decode(&ev); err != nil {
+			return nil, err
+		}
+
+		trace = append(trace, ev)
+	}
+
+	return trace, nil
+}
+
+// Replay installs a tracer on sim, calls run to drive the simulation (it
+// must be driven the same way as when trace was recorded, e.g. the same
+// process functions started in the same order), and reports whether the
+// resulting interleaving matches trace step by step in time, kind, process
+// and event. Payloads are not compared.
+func Replay(sim *Simulation, trace []TraceEvent, run func(*Simulation)) error {
+	var got []TraceEvent
+	sim.SetTracer(recorderFunc(func(ev TraceEvent) {
+		got = append(got, ev)
+	}))
+
+	run(sim)
+
+	if len(trace) != len(got) {
+		return fmt.Errorf("simgo: replay mismatch: recorded %d events, expected %d", len(got), len(trace))
+	}
+
+	for i, want := range trace {
+		have := got[i]
+
+		if have.Time != want.Time || have.Kind != want.Kind || have.Process != want.Process || have.Event != want.Event {
+			return fmt.Errorf("simgo: replay mismatch at seq %d: got %+v, want %+v", want.Seq, have, want)
+		}
+	}
+
+	return nil
+}
+
+// recorderFunc adapts a plain func(TraceEvent) to the Tracer interface.
+type recorderFunc func(TraceEvent)
+
+func (f recorderFunc) Trace(ev TraceEvent) {
+	f(ev)
+}
@@ -0,0 +1,170 @@
+// Copyright © 2021 Felix Schütz
+// Licensed under the MIT license. See the LICENSE file for details.
+
+package simgo
+
+import (
+	"bytes"
+	"testing"
+)
+
+// recordingTracer is a minimal Tracer that just appends everything it
+// receives, for assertions that don't need to round-trip through JSON.
+type recordingTracer struct {
+	events []TraceEvent
+}
+
+func (r *recordingTracer) Trace(ev TraceEvent) {
+	r.events = append(r.events, ev)
+}
+
+func TestTracerRecordsProcessLifecycle(t *testing.T) {
+	sim := NewSimulation()
+
+	tracer := &recordingTracer{}
+	sim.SetTracer(tracer)
+
+	sim.Process(func(proc Process) {
+		proc.Wait(proc.Timeout(1))
+	})
+
+	sim.Run()
+
+	kinds := map[TraceKind]int{}
+	for _, ev := range tracer.events {
+		kinds[ev.Kind]++
+	}
+
+	for _, want := range []TraceKind{TraceStarted, TraceWaited, TraceResumed} {
+		if kinds[want] == 0 {
+			t.Fatalf("no %q event recorded, got %+v", want, tracer.events)
+		}
+	}
+
+	for i := 1; i < len(tracer.events); i++ {
+		if tracer.events[i].Seq <= tracer.events[i-1].Seq {
+			t.Fatalf("Seq not strictly increasing at index %d: %+v", i, tracer.events)
+		}
+	}
+}
+
+// TestTracerRecordsAbort is a regression test for the abort path: waiting on
+// an already-aborted event (a closed Channel's SendEvent) must still report a
+// TraceAborted event, not just the non-abort kinds.
+func TestTracerRecordsAbort(t *testing.T) {
+	sim := NewSimulation()
+	ch := NewChannel[int](sim, 0)
+	ch.Close()
+
+	tracer := &recordingTracer{}
+	sim.SetTracer(tracer)
+
+	sim.Process(func(proc Process) {
+		proc.Wait(ch.SendEvent(1))
+	})
+
+	sim.Run()
+
+	for _, ev := range tracer.events {
+		if ev.Kind == TraceAborted {
+			return
+		}
+	}
+
+	t.Fatalf("no TraceAborted event recorded, got %+v", tracer.events)
+}
+
+func TestJSONLTracerRoundTrip(t *testing.T) {
+	sim := NewSimulation()
+
+	var buf bytes.Buffer
+	jsonl := NewJSONLTracer(&buf)
+	sim.SetTracer(jsonl)
+
+	sim.Process(func(proc Process) {
+		proc.Wait(proc.Timeout(1))
+	})
+
+	sim.Run()
+
+	if err := jsonl.Err(); err != nil {
+		t.Fatalf("Err() = %v, want nil", err)
+	}
+
+	trace, err := ReadTrace(&buf)
+	if err != nil {
+		t.Fatalf("ReadTrace() = %v, want nil error", err)
+	}
+
+	if len(trace) == 0 {
+		t.Fatal("ReadTrace() returned no events")
+	}
+}
+
+// TestReplayMatchesOriginalRun is a regression test for Replay: driving the
+// same deterministic simulation a second time, the same way, must reproduce
+// the exact same trace.
+func TestReplayMatchesOriginalRun(t *testing.T) {
+	sim := NewSimulation()
+
+	var buf bytes.Buffer
+	jsonl := NewJSONLTracer(&buf)
+	sim.SetTracer(jsonl)
+
+	run := func(sim *Simulation) {
+		sim.Process(func(proc Process) {
+			proc.Wait(proc.Timeout(1))
+		})
+
+		sim.Process(func(proc Process) {
+			proc.Wait(proc.Timeout(2))
+		})
+
+		sim.Run()
+	}
+
+	run(sim)
+
+	trace, err := ReadTrace(&buf)
+	if err != nil {
+		t.Fatalf("ReadTrace() = %v, want nil error", err)
+	}
+
+	if err := Replay(NewSimulation(), trace, run); err != nil {
+		t.Fatalf("Replay() = %v, want nil (same deterministic run should match exactly)", err)
+	}
+}
+
+// TestReplayDetectsMismatch is a regression test for Replay's other branch:
+// a run that behaves differently from the recorded trace must be reported,
+// not silently accepted.
+func TestReplayDetectsMismatch(t *testing.T) {
+	sim := NewSimulation()
+
+	var buf bytes.Buffer
+	jsonl := NewJSONLTracer(&buf)
+	sim.SetTracer(jsonl)
+
+	sim.Process(func(proc Process) {
+		proc.Wait(proc.Timeout(1))
+	})
+
+	sim.Run()
+
+	trace, err := ReadTrace(&buf)
+	if err != nil {
+		t.Fatalf("ReadTrace() = %v, want nil error", err)
+	}
+
+	err = Replay(NewSimulation(), trace, func(sim *Simulation) {
+		sim.Process(func(proc Process) {
+			proc.Wait(proc.Timeout(2)) // different delay than what was recorded
+		})
+
+		sim.Run()
+	})
+
+	if err == nil {
+		t.Fatal("Replay() = nil, want a mismatch error for a run that diverges from the recorded trace")
+	}
+}